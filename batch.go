@@ -0,0 +1,95 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"runtime"
+)
+
+// Result bundles the outcome of one Request executed as part of a Batch.
+type Result struct {
+	Response *http.Response
+	Body     []byte
+	Err      error
+}
+
+// Batch runs many requests concurrently, the natural extension point for
+// fan-out API calls (paginated fetches, bulk lookups, etc.) that compose
+// the same Request.Perform primitive.
+type Batch struct {
+	// Concurrency caps how many requests run at once. Concurrency<=0
+	// defaults to runtime.NumCPU().
+	Concurrency int
+
+	// StopOnError cancels the remaining in-flight requests as soon as
+	// one fails.
+	StopOnError bool
+}
+
+// Execute runs reqs[i].Perform(ctx, headers[i], bodies[i]) for every i,
+// using a pool of b.Concurrency worker goroutines, and returns one
+// Result per request in the same order as reqs. headers and bodies may
+// be nil, or shorter than reqs (missing entries are treated as nil).
+func (b Batch) Execute(ctx context.Context, reqs []Request, headers []map[string]any, bodies []any) []Result {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	concurrency := b.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	if concurrency > len(reqs) {
+		concurrency = len(reqs)
+	}
+
+	results := make([]Result, len(reqs))
+	if len(reqs) == 0 {
+		return results
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	indexes := make(chan int)
+	go func() {
+		defer close(indexes)
+		for i := range reqs {
+			select {
+			case indexes <- i:
+			case <-runCtx.Done():
+				return
+			}
+		}
+	}()
+
+	done := make(chan struct{})
+	for w := 0; w < concurrency; w++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			for i := range indexes {
+				var h map[string]any
+				if i < len(headers) {
+					h = headers[i]
+				}
+				var body any
+				if i < len(bodies) {
+					body = bodies[i]
+				}
+
+				resp, respBody, err := reqs[i].Perform(runCtx, h, body)
+				results[i] = Result{Response: resp, Body: respBody, Err: err}
+
+				if err != nil && b.StopOnError {
+					cancel()
+				}
+			}
+		}()
+	}
+
+	for w := 0; w < concurrency; w++ {
+		<-done
+	}
+
+	return results
+}
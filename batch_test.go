@@ -0,0 +1,100 @@
+package httpx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBatchExecutePreservesOrder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(r.URL.Query().Get("n")))
+	}))
+	defer srv.Close()
+
+	const n = 20
+	reqs := make([]Request, n)
+	bodies := make([]any, n)
+	for i := 0; i < n; i++ {
+		reqs[i] = Request{URL: fmt.Sprintf("%s?n=%d", srv.URL, i), Method: http.MethodGet, Type: BodyPlain}
+	}
+
+	b := Batch{Concurrency: 4}
+	results := b.Execute(context.TODO(), reqs, nil, bodies)
+
+	if len(results) != n {
+		t.Fatalf("expected %d results, got %d", n, len(results))
+	}
+	for i, res := range results {
+		if res.Err != nil {
+			t.Fatalf("request %d failed: %v", i, res.Err)
+		}
+		if want := fmt.Sprintf("%d", i); string(res.Body) != want {
+			t.Fatalf("result %d out of order: got body %q", i, string(res.Body))
+		}
+	}
+}
+
+func TestBatchDefaultConcurrencyUsesAllRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	reqs := []Request{
+		{URL: srv.URL, Method: http.MethodGet, Type: BodyJSON},
+		{URL: srv.URL, Method: http.MethodGet, Type: BodyJSON},
+		{URL: srv.URL, Method: http.MethodGet, Type: BodyJSON},
+	}
+
+	b := Batch{}
+	results := b.Execute(context.TODO(), reqs, nil, nil)
+
+	if len(results) != len(reqs) {
+		t.Fatalf("expected %d results, got %d", len(reqs), len(results))
+	}
+	for i, res := range results {
+		if res.Err != nil {
+			t.Fatalf("request %d failed: %v", i, res.Err)
+		}
+	}
+}
+
+func TestBatchStopOnErrorCancelsInFlight(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("n") == "0" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	const n = 8
+	reqs := make([]Request, n)
+	for i := 0; i < n; i++ {
+		reqs[i] = Request{URL: fmt.Sprintf("%s?n=%d", srv.URL, i), Method: http.MethodGet, Type: BodyPlain}
+	}
+
+	b := Batch{Concurrency: n, StopOnError: true}
+	results := b.Execute(context.TODO(), reqs, nil, nil)
+
+	if results[0].Err == nil {
+		t.Fatalf("expected request 0 to fail with a 500")
+	}
+
+	var canceled int
+	for i := 1; i < n; i++ {
+		if results[i].Err != nil {
+			canceled++
+		}
+	}
+	if canceled == 0 {
+		t.Fatalf("expected StopOnError to cancel at least one in-flight request")
+	}
+}
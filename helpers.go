@@ -5,10 +5,25 @@ import (
 	"encoding/xml"
 	"fmt"
 	"net/http"
+	"net/url"
 )
 
 func encodeBody(t BodyType, v any) ([]byte, string, error) {
 	switch t {
+	case BodyForm:
+		switch x := v.(type) {
+		case url.Values:
+			return []byte(x.Encode()), "application/x-www-form-urlencoded", nil
+		case map[string]string:
+			vals := make(url.Values, len(x))
+			for k, val := range x {
+				vals.Set(k, val)
+			}
+			return []byte(vals.Encode()), "application/x-www-form-urlencoded", nil
+		default:
+			return nil, "", fmt.Errorf("form body expects url.Values or map[string]string, got %T", v)
+		}
+
 	case BodyXML:
 		b, err := xml.Marshal(v)
 		if err != nil {
@@ -0,0 +1,54 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+)
+
+// Doer is anything capable of executing an *http.Request — the same shape
+// as *http.Client.Do. Middleware wraps a Doer to add cross-cutting
+// behavior (auth, tracing, metrics, circuit breaking) without reaching
+// into http.Client.Transport.
+type Doer interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// Middleware wraps a Doer to produce a new Doer, forming a chain.
+type Middleware func(next Doer) Doer
+
+// RequestHook runs before each attempt is sent.
+type RequestHook func(ctx context.Context, req *http.Request) error
+
+// ResponseHook runs once each attempt completes, after the body has been
+// read. resp is nil if the attempt failed before a response was received,
+// in which case err holds the cause.
+type ResponseHook func(ctx context.Context, resp *http.Response, err error) error
+
+type attemptContextKey struct{}
+
+// AttemptFromContext returns the 1-based attempt number of the request
+// currently in flight, as seen by middleware and hooks during Perform or
+// PerformStream. ok is false if ctx did not come from either.
+func AttemptFromContext(ctx context.Context) (attempt int, ok bool) {
+	attempt, ok = ctx.Value(attemptContextKey{}).(int)
+	return attempt, ok
+}
+
+func withAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptContextKey{}, attempt)
+}
+
+// doerFunc adapts a plain function to the Doer interface.
+type doerFunc func(*http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(req *http.Request) (*http.Response, error) { return f(req) }
+
+// buildChain wraps base with middlewares in order: the first middleware
+// in the slice is outermost and sees the request first.
+func buildChain(base Doer, middlewares []Middleware) Doer {
+	d := base
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		d = middlewares[i](d)
+	}
+	return d
+}
@@ -0,0 +1,162 @@
+package httpx
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// BearerToken returns a Middleware that sets the Authorization header to
+// "Bearer <token>" on every request. source is called on each attempt
+// (not just once per Perform call), so it can refresh an expired token
+// transparently across retries.
+func BearerToken(source func(ctx context.Context) (string, error)) Middleware {
+	return func(next Doer) Doer {
+		return doerFunc(func(req *http.Request) (*http.Response, error) {
+			token, err := source(req.Context())
+			if err != nil {
+				return nil, fmt.Errorf("bearer token: %w", err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			return next.Do(req)
+		})
+	}
+}
+
+// GzipDecode returns a Middleware that requests gzip-encoded responses
+// and transparently decompresses them, so callers always see plain bytes
+// regardless of what the server sent.
+//
+// Because it sets Accept-Encoding on the request itself, net/http's
+// Transport leaves the gzip encoding in place for this middleware to
+// remove, whatever the Transport's DisableCompression setting.
+func GzipDecode() Middleware {
+	return func(next Doer) Doer {
+		return doerFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("Accept-Encoding") == "" {
+				req.Header.Set("Accept-Encoding", "gzip")
+			}
+
+			resp, err := next.Do(req)
+			if err != nil || resp == nil || resp.Header.Get("Content-Encoding") != "gzip" {
+				return resp, err
+			}
+
+			gz, err := gzip.NewReader(resp.Body)
+			if err != nil {
+				resp.Body.Close()
+				return nil, fmt.Errorf("gzip decode: %w", err)
+			}
+			resp.Body = &gzipReadCloser{gz: gz, orig: resp.Body}
+			resp.Header.Del("Content-Encoding")
+			resp.Header.Del("Content-Length")
+			resp.ContentLength = -1
+			return resp, nil
+		})
+	}
+}
+
+type gzipReadCloser struct {
+	gz   *gzip.Reader
+	orig io.ReadCloser
+}
+
+func (g *gzipReadCloser) Read(p []byte) (int, error) { return g.gz.Read(p) }
+
+func (g *gzipReadCloser) Close() error {
+	gzErr := g.gz.Close()
+	origErr := g.orig.Close()
+	if gzErr != nil {
+		return gzErr
+	}
+	return origErr
+}
+
+const (
+	DefaultCircuitThreshold  = 5
+	DefaultCircuitResetAfter = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned by CircuitBreaker's middleware when a host's
+// circuit has tripped and is not yet due for a trial request.
+var ErrCircuitOpen = errors.New("httpx: circuit open")
+
+// CircuitBreaker trips per-host after Threshold consecutive failures
+// (transport errors or 5xx responses) and rejects further requests to
+// that host with ErrCircuitOpen until ResetAfter has elapsed, at which
+// point a single trial request is allowed through to probe recovery.
+// The zero value uses DefaultCircuitThreshold and DefaultCircuitResetAfter.
+type CircuitBreaker struct {
+	Threshold  int
+	ResetAfter time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*circuitState
+}
+
+type circuitState struct {
+	failures      int
+	openUntil     time.Time
+	trialInFlight bool
+}
+
+// Middleware returns the Middleware enforcing this breaker's policy.
+// A single CircuitBreaker can be shared across many Requests to track
+// host health across them.
+func (cb *CircuitBreaker) Middleware() Middleware {
+	return func(next Doer) Doer {
+		return doerFunc(func(req *http.Request) (*http.Response, error) {
+			host := req.URL.Host
+
+			cb.mu.Lock()
+			if cb.hosts == nil {
+				cb.hosts = make(map[string]*circuitState)
+			}
+			st, ok := cb.hosts[host]
+			if !ok {
+				st = &circuitState{}
+				cb.hosts[host] = st
+			}
+			tripped := !st.openUntil.IsZero()
+			if tripped {
+				if st.openUntil.After(time.Now()) || st.trialInFlight {
+					cb.mu.Unlock()
+					return nil, ErrCircuitOpen
+				}
+				// ResetAfter has elapsed and no trial is outstanding: let
+				// exactly this one request through to probe recovery.
+				st.trialInFlight = true
+			}
+			cb.mu.Unlock()
+
+			resp, err := next.Do(req)
+
+			cb.mu.Lock()
+			defer cb.mu.Unlock()
+			st.trialInFlight = false
+			if err != nil || (resp != nil && resp.StatusCode >= 500) {
+				st.failures++
+				threshold := cb.Threshold
+				if threshold <= 0 {
+					threshold = DefaultCircuitThreshold
+				}
+				if st.failures >= threshold {
+					resetAfter := cb.ResetAfter
+					if resetAfter <= 0 {
+						resetAfter = DefaultCircuitResetAfter
+					}
+					st.openUntil = time.Now().Add(resetAfter)
+				}
+			} else {
+				st.failures = 0
+				st.openUntil = time.Time{}
+			}
+			return resp, err
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package httpx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMiddlewareChainOrderAndAttempts(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&hits, 1)
+		if n < 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	var order []string
+	var attempts []int
+	mark := func(name string) Middleware {
+		return func(next Doer) Doer {
+			return doerFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				if n, ok := AttemptFromContext(req.Context()); ok {
+					attempts = append(attempts, n)
+				}
+				return next.Do(req)
+			})
+		}
+	}
+
+	req := Request{
+		URL:         srv.URL,
+		Method:      http.MethodGet,
+		Type:        BodyJSON,
+		Retry:       true,
+		Middlewares: []Middleware{mark("outer"), mark("inner")},
+	}
+
+	_, body, err := req.Perform(context.TODO(), nil, nil)
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if string(body) != "ok" {
+		t.Fatalf("unexpected body: %q", string(body))
+	}
+	if len(order) != 4 || order[0] != "outer" || order[1] != "inner" {
+		t.Fatalf("unexpected middleware order: %v", order)
+	}
+	if len(attempts) != 4 || attempts[0] != 1 || attempts[2] != 2 {
+		t.Fatalf("unexpected attempt numbers: %v", attempts)
+	}
+}
+
+func TestRequestAndResponseHooksFire(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	var reqSeen, respSeen int32
+	req := Request{
+		URL:    srv.URL,
+		Method: http.MethodGet,
+		Type:   BodyJSON,
+		RequestHook: func(ctx context.Context, r *http.Request) error {
+			atomic.AddInt32(&reqSeen, 1)
+			return nil
+		},
+		ResponseHook: func(ctx context.Context, resp *http.Response, err error) error {
+			atomic.AddInt32(&respSeen, 1)
+			return nil
+		},
+	}
+
+	_, _, err := req.Perform(context.TODO(), nil, nil)
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if atomic.LoadInt32(&reqSeen) != 1 || atomic.LoadInt32(&respSeen) != 1 {
+		t.Fatalf("expected each hook once, got req=%d resp=%d", reqSeen, respSeen)
+	}
+}
+
+func TestBearerTokenRefreshesPerAttempt(t *testing.T) {
+	var gotTokens []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTokens = append(gotTokens, r.Header.Get("Authorization"))
+		if len(gotTokens) < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	var calls int32
+	source := func(ctx context.Context) (string, error) {
+		n := atomic.AddInt32(&calls, 1)
+		return fmt.Sprintf("token-%d", n), nil
+	}
+
+	req := Request{
+		URL:         srv.URL,
+		Method:      http.MethodGet,
+		Type:        BodyJSON,
+		Retry:       true,
+		Middlewares: []Middleware{BearerToken(source)},
+	}
+
+	_, _, err := req.Perform(context.TODO(), nil, nil)
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+
+	if len(gotTokens) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(gotTokens))
+	}
+	if gotTokens[0] != "Bearer token-1" {
+		t.Fatalf("expected first attempt to use token-1, got %q", gotTokens[0])
+	}
+	if gotTokens[1] != "Bearer token-2" {
+		t.Fatalf("expected retried attempt to use a refreshed token-2, got %q", gotTokens[1])
+	}
+}
+
+func TestGzipDecodeMiddleware(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, _ = gz.Write([]byte("hello gzip"))
+		gz.Close()
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(buf.Bytes())
+	}))
+	defer srv.Close()
+
+	req := Request{
+		URL:         srv.URL,
+		Method:      http.MethodGet,
+		Type:        BodyJSON,
+		Middlewares: []Middleware{GzipDecode()},
+	}
+
+	_, body, err := req.Perform(context.TODO(), nil, nil)
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if string(body) != "hello gzip" {
+		t.Fatalf("expected decoded body, got %q", string(body))
+	}
+}
+
+func TestCircuitBreakerTripsAndRejects(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	cb := &CircuitBreaker{Threshold: 2, ResetAfter: time.Hour}
+
+	req := Request{
+		URL:         srv.URL,
+		Method:      http.MethodGet,
+		Type:        BodyJSON,
+		Middlewares: []Middleware{cb.Middleware()},
+	}
+
+	for i := 0; i < 2; i++ {
+		_, _, _ = req.Perform(context.TODO(), nil, nil)
+	}
+
+	_, _, err := req.Perform(context.TODO(), nil, nil)
+	if err == nil || !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected circuit open error, got %v", err)
+	}
+}
+
+func TestCircuitBreakerHalfOpenAllowsOnlyOneTrial(t *testing.T) {
+	var hits int32
+	trialStarted := make(chan struct{})
+	releaseTrial := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&hits, 1) {
+		case 1:
+			w.WriteHeader(http.StatusInternalServerError)
+		case 2:
+			close(trialStarted)
+			<-releaseTrial
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer srv.Close()
+
+	cb := &CircuitBreaker{Threshold: 1, ResetAfter: 10 * time.Millisecond}
+
+	req := Request{
+		URL:         srv.URL,
+		Method:      http.MethodGet,
+		Type:        BodyJSON,
+		Middlewares: []Middleware{cb.Middleware()},
+	}
+
+	// Trip the circuit with a single failure, then wait past ResetAfter
+	// so the next request is eligible to probe as a half-open trial.
+	_, _, _ = req.Perform(context.TODO(), nil, nil)
+	time.Sleep(20 * time.Millisecond)
+
+	go func() {
+		_, _, _ = req.Perform(context.TODO(), nil, nil)
+	}()
+	<-trialStarted
+
+	const rejects = 5
+	errs := make(chan error, rejects)
+	for i := 0; i < rejects; i++ {
+		go func() {
+			_, _, err := req.Perform(context.TODO(), nil, nil)
+			errs <- err
+		}()
+	}
+	for i := 0; i < rejects; i++ {
+		if err := <-errs; !errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("expected every concurrent request during the trial to get ErrCircuitOpen, got %v", err)
+		}
+	}
+	close(releaseTrial)
+
+	time.Sleep(20 * time.Millisecond)
+	if got := atomic.LoadInt32(&hits); got != 2 {
+		t.Fatalf("expected exactly 2 requests to reach the server (the tripping failure + the single trial), got %d", got)
+	}
+}
@@ -0,0 +1,220 @@
+package httpx
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+)
+
+// FileUpload is one file part of a Multipart body.
+type FileUpload struct {
+	FieldName   string
+	Filename    string
+	ContentType string
+	Reader      io.Reader
+}
+
+// Multipart is the body for BodyMultipart: ordinary form fields plus file
+// parts, encoded with mime/multipart.Writer.
+type Multipart struct {
+	Fields map[string]string
+	Files  []FileUpload
+}
+
+// writeParts writes m's fields and files into mw and closes it. It does
+// not set mw's boundary or content type — callers read that off mw
+// before or after calling this, as needed.
+func (m Multipart) writeParts(mw *multipart.Writer) error {
+	for k, v := range m.Fields {
+		if err := mw.WriteField(k, v); err != nil {
+			return fmt.Errorf("multipart field %q: %w", k, err)
+		}
+	}
+	for _, f := range m.Files {
+		ct := f.ContentType
+		if ct == "" {
+			ct = "application/octet-stream"
+		}
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%q; filename=%q`, f.FieldName, f.Filename))
+		h.Set("Content-Type", ct)
+		part, err := mw.CreatePart(h)
+		if err != nil {
+			return fmt.Errorf("multipart part %q: %w", f.FieldName, err)
+		}
+		if _, err := io.Copy(part, f.Reader); err != nil {
+			return fmt.Errorf("multipart copy %q: %w", f.FieldName, err)
+		}
+	}
+	return mw.Close()
+}
+
+// writeTo writes the full encoded body to w and returns the content type
+// (including boundary) to send with it.
+func (m Multipart) writeTo(w io.Writer) (string, error) {
+	mw := multipart.NewWriter(w)
+	if err := m.writeParts(mw); err != nil {
+		return "", err
+	}
+	return mw.FormDataContentType(), nil
+}
+
+// seekable reports whether every file reader can be rewound, which is
+// what lets a multipart body be re-sent on retry without buffering it
+// to disk first.
+func (m Multipart) seekable() bool {
+	for _, f := range m.Files {
+		if _, ok := f.Reader.(io.Seeker); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (m Multipart) rewindFiles() error {
+	for _, f := range m.Files {
+		if s, ok := f.Reader.(io.Seeker); ok {
+			if _, err := s.Seek(0, io.SeekStart); err != nil {
+				return fmt.Errorf("rewind file %q: %w", f.FieldName, err)
+			}
+		}
+	}
+	return nil
+}
+
+// bodySource produces the request body for one attempt at a time. Plain
+// bodies (JSON/XML/plain/form) are small enough to hold in memory for the
+// whole Perform call and are served from a []byte. Multipart bodies are
+// handled specially (see buildBodySource) since file readers generally
+// cannot be re-read: they're either rewound in place, buffered to disk
+// once, or streamed straight through for a single, non-retried attempt.
+type bodySource interface {
+	// open returns a fresh reader and content type for one attempt.
+	open() (io.Reader, string, error)
+	// close releases any resources (e.g. a temp file) held across attempts.
+	close()
+}
+
+type bytesBodySource struct {
+	payload     []byte
+	contentType string
+}
+
+func (s *bytesBodySource) open() (io.Reader, string, error) {
+	return bytes.NewReader(s.payload), s.contentType, nil
+}
+
+func (s *bytesBodySource) close() {}
+
+// multipartMemSource re-encodes Multipart into memory before every
+// attempt. Used when every file reader is seekable, so each attempt can
+// rewind and re-copy the files without buffering the whole body to disk.
+type multipartMemSource struct {
+	mp Multipart
+}
+
+func (s *multipartMemSource) open() (io.Reader, string, error) {
+	if err := s.mp.rewindFiles(); err != nil {
+		return nil, "", err
+	}
+	var buf bytes.Buffer
+	ct, err := s.mp.writeTo(&buf)
+	if err != nil {
+		return nil, "", err
+	}
+	return &buf, ct, nil
+}
+
+func (s *multipartMemSource) close() {}
+
+// multipartDiskSource buffers Multipart to a temp file once, then seeks
+// back to the start for each attempt. Used for retryable requests whose
+// file readers are not seekable, since the temp file is.
+type multipartDiskSource struct {
+	f  *os.File
+	ct string
+}
+
+func newMultipartDiskSource(mp Multipart) (*multipartDiskSource, error) {
+	f, err := os.CreateTemp("", "httpx-multipart-*")
+	if err != nil {
+		return nil, fmt.Errorf("buffer multipart to disk: %w", err)
+	}
+	ct, err := mp.writeTo(f)
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return &multipartDiskSource{f: f, ct: ct}, nil
+}
+
+func (s *multipartDiskSource) open() (io.Reader, string, error) {
+	if _, err := s.f.Seek(0, io.SeekStart); err != nil {
+		return nil, "", fmt.Errorf("rewind temp multipart file: %w", err)
+	}
+	// Hide *os.File's Close method: net/http uses an io.ReadCloser body
+	// as-is and closes it once the request completes, which would leave
+	// it unusable for the next retry attempt.
+	return struct{ io.Reader }{s.f}, s.ct, nil
+}
+
+func (s *multipartDiskSource) close() {
+	s.f.Close()
+	os.Remove(s.f.Name())
+}
+
+// multipartStreamSource streams Multipart straight through an io.Pipe
+// without buffering it anywhere. It supports exactly one attempt; Perform
+// only uses it when retries are already off (e.g. a single-attempt
+// request, or a non-idempotent method).
+type multipartStreamSource struct {
+	mp Multipart
+}
+
+func (s *multipartStreamSource) open() (io.Reader, string, error) {
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	ct := mw.FormDataContentType()
+	go func() {
+		pw.CloseWithError(s.mp.writeParts(mw))
+	}()
+	return pr, ct, nil
+}
+
+func (s *multipartStreamSource) close() {}
+
+// buildBodySource picks the right bodySource for body given r.Type and
+// how many attempts Perform may make. For BodyMultipart this is the
+// documented trade-off: retries on a non-seekable upload require either
+// buffering to disk or giving up on retrying it.
+func buildBodySource(r Request, body any, attempts int) (bodySource, error) {
+	if body == nil {
+		return nil, nil
+	}
+
+	if r.Type != BodyMultipart {
+		payload, ct, err := encodeBody(r.Type, body)
+		if err != nil {
+			return nil, err
+		}
+		return &bytesBodySource{payload: payload, contentType: ct}, nil
+	}
+
+	mp, ok := body.(Multipart)
+	if !ok {
+		return nil, fmt.Errorf("multipart body expects httpx.Multipart, got %T", body)
+	}
+
+	switch {
+	case attempts <= 1:
+		return &multipartStreamSource{mp: mp}, nil
+	case mp.seekable():
+		return &multipartMemSource{mp: mp}, nil
+	default:
+		return newMultipartDiskSource(mp)
+	}
+}
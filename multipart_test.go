@@ -0,0 +1,163 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFormBodyURLValues(t *testing.T) {
+	var gotContentType string
+	var gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotContentType = r.Header.Get("Content-Type")
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	req := Request{URL: srv.URL, Method: http.MethodPost, Type: BodyForm}
+	_, _, err := req.Perform(context.TODO(), nil, url.Values{"q": {"search term"}})
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if gotContentType != "application/x-www-form-urlencoded" {
+		t.Fatalf("unexpected content type: %q", gotContentType)
+	}
+	if gotBody != "q=search+term" {
+		t.Fatalf("unexpected body: %q", gotBody)
+	}
+}
+
+func TestMultipartBodySendsFieldsAndFiles(t *testing.T) {
+	var gotFields map[string]string
+	var gotFile string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mt, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || !strings.HasPrefix(mt, "multipart/") {
+			t.Errorf("unexpected content type: %q (%v)", r.Header.Get("Content-Type"), err)
+			return
+		}
+		mr := multipart.NewReader(r.Body, params["boundary"])
+		gotFields = map[string]string{}
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Errorf("next part: %v", err)
+				return
+			}
+			data, _ := io.ReadAll(part)
+			if part.FileName() != "" {
+				gotFile = string(data)
+			} else {
+				gotFields[part.FormName()] = string(data)
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	req := Request{URL: srv.URL, Method: http.MethodPost, Type: BodyMultipart}
+	mp := Multipart{
+		Fields: map[string]string{"title": "report"},
+		Files: []FileUpload{
+			{FieldName: "file", Filename: "a.txt", ContentType: "text/plain", Reader: strings.NewReader("file contents")},
+		},
+	}
+
+	_, _, err := req.Perform(context.TODO(), nil, mp)
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if gotFields["title"] != "report" {
+		t.Fatalf("unexpected fields: %v", gotFields)
+	}
+	if gotFile != "file contents" {
+		t.Fatalf("unexpected file contents: %q", gotFile)
+	}
+}
+
+func TestMultipartRetryWithSeekableFileBuffersInMemory(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&hits, 1)
+		_, _ = io.Copy(io.Discard, r.Body)
+		if n < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	req := Request{URL: srv.URL, Method: http.MethodPut, Type: BodyMultipart, Retry: true}
+	mp := Multipart{
+		Files: []FileUpload{
+			{FieldName: "file", Filename: "a.txt", Reader: bytes.NewReader([]byte("seekable payload"))},
+		},
+	}
+
+	_, _, err := req.Perform(context.TODO(), nil, mp)
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if got := atomic.LoadInt32(&hits); got != 2 {
+		t.Fatalf("expected 2 attempts, got %d", got)
+	}
+}
+
+type onceReader struct {
+	r    io.Reader
+	used bool
+}
+
+func (o *onceReader) Read(p []byte) (int, error) {
+	o.used = true
+	return o.r.Read(p)
+}
+
+func TestMultipartRetryWithNonSeekableFileBuffersToDisk(t *testing.T) {
+	var hits int32
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&hits, 1)
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if n < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	req := Request{URL: srv.URL, Method: http.MethodPut, Type: BodyMultipart, Retry: true}
+	mp := Multipart{
+		Files: []FileUpload{
+			{FieldName: "file", Filename: "a.txt", Reader: &onceReader{r: strings.NewReader("non-seekable payload")}},
+		},
+	}
+
+	_, _, err := req.Perform(context.TODO(), nil, mp)
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if got := atomic.LoadInt32(&hits); got != 2 {
+		t.Fatalf("expected 2 attempts, got %d", got)
+	}
+	if len(bodies) != 2 || bodies[0] != bodies[1] {
+		t.Fatalf("expected identical re-sent bodies, got %v", bodies)
+	}
+}
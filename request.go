@@ -1,11 +1,12 @@
 package httpx
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptrace"
 	"strings"
 	"time"
 )
@@ -13,9 +14,11 @@ import (
 type BodyType string
 
 const (
-	BodyJSON  BodyType = "json"
-	BodyXML   BodyType = "xml"
-	BodyPlain BodyType = "plain"
+	BodyJSON      BodyType = "json"
+	BodyXML       BodyType = "xml"
+	BodyPlain     BodyType = "plain"
+	BodyForm      BodyType = "form"
+	BodyMultipart BodyType = "multipart"
 )
 
 const DefaultTimeout = 15 * time.Second
@@ -32,29 +35,73 @@ type Request struct {
 	// If provided and timeout override is passed to Perform, a shallow copy is used
 	// to apply the timeout without mutating the original client.
 	Client *http.Client
-}
 
-// Perform executes the request.
-// - ctx: cancellation/deadlines
-// - headers: map values can be string, []string, numbers, bool, etc.
-// - body: nil for no body; any value for JSON/XML; string/[]byte for plain
-// - timeout: optional override, defaults to DefaultTimeout
-func (r Request) Perform(
-	ctx context.Context,
-	headers map[string]interface{},
-	body any,
-	timeout ...time.Duration,
-) (*http.Response, []byte, error) {
+	// MaxBackoff caps the delay between retries, including any delay
+	// derived from a Retry-After response header. Defaults to DefaultMaxBackoff.
+	MaxBackoff time.Duration
 
-	if ctx == nil {
-		ctx = context.Background()
-	}
+	// Jitter randomizes the computed backoff (full jitter: a uniform draw
+	// between 0 and the computed duration) to avoid thundering-herd retries.
+	Jitter bool
+
+	// Backoff, if set, overrides the built-in backoff policy entirely.
+	// attempt is the 1-based attempt that just failed; resp is that
+	// attempt's response, or nil if it errored before a response was
+	// received.
+	Backoff func(attempt int, resp *http.Response) time.Duration
+
+	// Middlewares wraps the underlying Doer (ordinarily the *http.Client)
+	// with cross-cutting behavior. The chain is built once per Perform
+	// call, so every retry attempt passes through it; the first
+	// middleware in the slice is outermost.
+	Middlewares []Middleware
+
+	// RequestHook, if set, runs before each attempt is sent.
+	RequestHook RequestHook
+
+	// ResponseHook, if set, runs once each attempt completes (after the
+	// body has been read, or immediately if the attempt errored first).
+	ResponseHook ResponseHook
+
+	// MaxBodyBytes caps how much of a response body Perform/PerformStream
+	// will read. Zero means unlimited. Exceeding it yields ErrBodyTooLarge.
+	MaxBodyBytes int64
+
+	// DecodeInto, if set, tells Perform to stream-decode a successful
+	// response body directly into it (via json.Decoder or xml.Decoder,
+	// chosen from the response Content-Type, falling back to Type) rather
+	// than buffering the body and unmarshalling it separately. When set,
+	// Perform's []byte return value is nil on success.
+	DecodeInto any
 
+	// Trace enables per-attempt httptrace instrumentation (DNS, connect,
+	// TLS, time-to-first-byte) and emits one structured record per
+	// attempt via Logger. It never mutates the response, and never logs
+	// body contents unless LogBodies is also set.
+	Trace bool
+
+	// Logger receives Trace's records. Defaults to SlogLogger{} (logs to
+	// slog.Default()) when Trace is enabled and Logger is nil; set
+	// NoopLogger{} to discard the records. The timings are passed only to
+	// Logger; hooks and middleware cannot read them.
+	Logger Logger
+
+	// LogBodies additionally logs a truncated copy of the response body
+	// alongside Trace's timing record. Ignored when Trace is false.
+	LogBodies bool
+}
+
+// prepare validates r, applies defaults, builds a bodySource (so retries
+// don't suffer from consumed readers), and builds the client/middleware
+// chain shared by every attempt. It mutates *r to fill in defaults
+// (Type), which both Perform and PerformStream rely on afterward. The
+// caller must call src.close() (if src is non-nil) once done.
+func (r *Request) prepare(body any, timeout ...time.Duration) (client *http.Client, chain Doer, attempts int, src bodySource, err error) {
 	if strings.TrimSpace(r.URL) == "" {
-		return nil, nil, fmt.Errorf("URL is empty")
+		return nil, nil, 0, nil, fmt.Errorf("URL is empty")
 	}
 	if strings.TrimSpace(r.Method) == "" {
-		return nil, nil, fmt.Errorf("Method is empty")
+		return nil, nil, 0, nil, fmt.Errorf("Method is empty")
 	}
 	if r.Type == "" {
 		r.Type = BodyJSON
@@ -65,26 +112,23 @@ func (r Request) Perform(
 		t = timeout[0]
 	}
 
-	// Encode body once so retries don't suffer from consumed readers.
-	var payload []byte
-	var contentType string
-	var err error
-
-	if body != nil {
-		payload, contentType, err = encodeBody(r.Type, body)
-		if err != nil {
-			return nil, nil, err
-		}
-	}
-
-	attempts := 1
+	attempts = 1
 	if r.Retry && isIdempotentMethod(r.Method) {
 		attempts = DefaultRetryAttempts
 	}
 
-	var client *http.Client
+	src, err = buildBodySource(*r, body, attempts)
+	if err != nil {
+		return nil, nil, 0, nil, err
+	}
+
 	if r.Client == nil {
-		client = &http.Client{Timeout: t}
+		// DisableCompression: true so middleware sees the response as the
+		// server actually sent it; otherwise, for requests that carry no
+		// Accept-Encoding header of their own, the transport negotiates
+		// and strips gzip encoding transparently before any Doer in the
+		// chain runs.
+		client = &http.Client{Timeout: t, Transport: &http.Transport{DisableCompression: true}}
 	} else if len(timeout) > 0 && timeout[0] > 0 {
 		c := *r.Client
 		c.Timeout = t
@@ -93,6 +137,105 @@ func (r Request) Perform(
 		client = r.Client
 	}
 
+	chain = buildChain(client, r.Middlewares)
+	return client, chain, attempts, src, nil
+}
+
+// newAttemptRequest builds the *http.Request for one attempt: it opens a
+// fresh body from src, applies headers, default Content-Type/Accept, and
+// runs RequestHook.
+func (r Request) newAttemptRequest(attemptCtx context.Context, headers map[string]interface{}, body any, src bodySource) (*http.Request, error) {
+	var reader io.Reader
+	var contentType string
+	if body != nil {
+		var err error
+		reader, contentType, err = src.open()
+		if err != nil {
+			return nil, fmt.Errorf("encode body: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(attemptCtx, r.Method, r.URL, reader)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	applyHeaders(req.Header, headers)
+
+	// Content-Type if we have a body and user didn't override it.
+	if body != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	// Default Accept if not set.
+	if req.Header.Get("Accept") == "" {
+		switch r.Type {
+		case BodyXML:
+			req.Header.Set("Accept", "application/xml")
+		case BodyPlain:
+			req.Header.Set("Accept", "text/plain")
+		default:
+			req.Header.Set("Accept", "application/json")
+		}
+	}
+
+	if r.RequestHook != nil {
+		if hookErr := r.RequestHook(attemptCtx, req); hookErr != nil {
+			return nil, fmt.Errorf("request hook: %w", hookErr)
+		}
+	}
+
+	return req, nil
+}
+
+// logAttempt emits Trace's structured record for one attempt. No-op
+// unless r.Trace is set.
+func (r Request) logAttempt(ctx context.Context, timings *traceTimings, started time.Time, req *http.Request, attempt, status int, bytesIn int64, respBody []byte) {
+	if !r.Trace {
+		return
+	}
+	logger := r.Logger
+	if logger == nil {
+		logger = SlogLogger{}
+	}
+
+	headerNames := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		headerNames = append(headerNames, name)
+	}
+
+	fields := timings.fields(started, req.Method, req.URL.String(), attempt, status, bytesIn, req.ContentLength, headerNames)
+	if r.LogBodies && len(respBody) > 0 {
+		fields = append(fields, "body", truncate(string(respBody), 2048))
+	}
+	logger.Log(ctx, "httpx.request", fields...)
+}
+
+// Perform executes the request.
+//   - ctx: cancellation/deadlines
+//   - headers: map values can be string, []string, numbers, bool, etc.
+//   - body: nil for no body; any value for JSON/XML; string/[]byte for plain;
+//     url.Values/map[string]string for BodyForm; Multipart for BodyMultipart
+//   - timeout: optional override, defaults to DefaultTimeout
+func (r Request) Perform(
+	ctx context.Context,
+	headers map[string]interface{},
+	body any,
+	timeout ...time.Duration,
+) (*http.Response, []byte, error) {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	_, chain, attempts, src, err := r.prepare(body, timeout...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if src != nil {
+		defer src.close()
+	}
+
 	var lastResp *http.Response
 	var lastBody []byte
 
@@ -101,50 +244,85 @@ func (r Request) Perform(
 			return lastResp, lastBody, err
 		}
 
-		var reader io.Reader
-		if body != nil {
-			reader = bytes.NewReader(payload)
-		}
-
-		req, err := http.NewRequestWithContext(ctx, r.Method, r.URL, reader)
-		if err != nil {
-			return nil, nil, fmt.Errorf("create request: %w", err)
-		}
+		attemptCtx := withAttempt(ctx, i)
 
-		applyHeaders(req.Header, headers)
-
-		// Content-Type if we have a body and user didn't override it.
-		if body != nil && req.Header.Get("Content-Type") == "" {
-			req.Header.Set("Content-Type", contentType)
+		var timings *traceTimings
+		attemptStart := time.Now()
+		if r.Trace {
+			timings = &traceTimings{}
+			attemptCtx = httptrace.WithClientTrace(attemptCtx, newClientTrace(timings))
 		}
 
-		// Default Accept if not set.
-		if req.Header.Get("Accept") == "" {
-			switch r.Type {
-			case BodyXML:
-				req.Header.Set("Accept", "application/xml")
-			case BodyPlain:
-				req.Header.Set("Accept", "text/plain")
-			default:
-				req.Header.Set("Accept", "application/json")
-			}
+		req, err := r.newAttemptRequest(attemptCtx, headers, body, src)
+		if err != nil {
+			return lastResp, lastBody, err
 		}
 
-		resp, err := client.Do(req)
+		resp, err := chain.Do(req)
 		if err != nil {
+			if r.ResponseHook != nil {
+				if hookErr := r.ResponseHook(attemptCtx, nil, err); hookErr != nil {
+					return lastResp, lastBody, fmt.Errorf("response hook: %w", hookErr)
+				}
+			}
+			if r.Trace {
+				r.logAttempt(attemptCtx, timings, attemptStart, req, i, 0, 0, nil)
+			}
 			if r.Retry && i < attempts && isRetryableError(err) {
-				sleepBackoff(ctx, i)
+				sleepBackoff(ctx, nextBackoff(r, i, nil))
 				continue
 			}
 			return lastResp, lastBody, fmt.Errorf("do request: %w", err)
 		}
 
-		respBody, readErr := io.ReadAll(resp.Body)
+		retryableStatus := r.Retry && i < attempts && isRetryableStatus(resp.StatusCode)
+
+		// Stream-decode straight from the wire on a final, successful
+		// attempt, instead of buffering the whole body just to unmarshal
+		// it a moment later.
+		if r.DecodeInto != nil && !retryableStatus && resp.StatusCode < 400 {
+			limited := limitBody(resp.Body, r.MaxBodyBytes)
+			decErr := decodeInto(limited, resp.Header.Get("Content-Type"), r.Type, r.DecodeInto)
+			resp.Body.Close()
+			if r.ResponseHook != nil {
+				if hookErr := r.ResponseHook(attemptCtx, resp, decErr); hookErr != nil {
+					return resp, nil, fmt.Errorf("response hook: %w", hookErr)
+				}
+			}
+			if r.Trace {
+				r.logAttempt(attemptCtx, timings, attemptStart, req, i, resp.StatusCode, limited.n, nil)
+			}
+			if decErr != nil && decErr != io.EOF {
+				if errors.Is(decErr, ErrBodyTooLarge) {
+					return resp, nil, decErr
+				}
+				return resp, nil, fmt.Errorf("decode response: %w", decErr)
+			}
+			return resp, nil, nil
+		}
+
+		limited := limitBody(resp.Body, r.MaxBodyBytes)
+		respBody, readErr := io.ReadAll(limited)
 		resp.Body.Close()
+		if r.ResponseHook != nil {
+			if hookErr := r.ResponseHook(attemptCtx, resp, readErr); hookErr != nil {
+				return resp, respBody, fmt.Errorf("response hook: %w", hookErr)
+			}
+		}
+		if r.Trace {
+			r.logAttempt(attemptCtx, timings, attemptStart, req, i, resp.StatusCode, limited.n, respBody)
+		}
 		if readErr != nil {
+			if errors.Is(readErr, ErrBodyTooLarge) {
+				if retryableStatus {
+					sleepBackoff(ctx, nextBackoff(r, i, resp))
+					continue
+				}
+				return resp, nil, readErr
+			}
 			if r.Retry && i < attempts {
 				lastResp, lastBody = resp, respBody
-				sleepBackoff(ctx, i)
+				sleepBackoff(ctx, nextBackoff(r, i, resp))
 				continue
 			}
 			return resp, nil, fmt.Errorf("read response: %w", readErr)
@@ -153,8 +331,8 @@ func (r Request) Perform(
 		lastResp, lastBody = resp, respBody
 
 		// Retry on selected HTTP status codes
-		if r.Retry && i < attempts && isRetryableStatus(resp.StatusCode) {
-			sleepBackoff(ctx, i)
+		if retryableStatus {
+			sleepBackoff(ctx, nextBackoff(r, i, resp))
 			continue
 		}
 
@@ -172,3 +350,68 @@ func (r Request) Perform(
 	}
 	return lastResp, lastBody, fmt.Errorf("request failed after %d attempts", attempts)
 }
+
+// PerformStream is like Perform but returns the response body unread,
+// for callers that want to stream large downloads instead of buffering
+// them fully in memory. Retries are still safe: the request body (if
+// any) is served from a bodySource built once up front.
+//
+// Unlike Perform, PerformStream does not treat 4xx/5xx status codes as
+// errors, since doing so would require reading the body it's meant to
+// leave untouched — callers should check resp.StatusCode themselves.
+// The caller owns the returned io.ReadCloser and must close it.
+//
+// If MaxBodyBytes is set, reads past the limit return ErrBodyTooLarge
+// instead of silently truncating.
+func (r Request) PerformStream(
+	ctx context.Context,
+	headers map[string]interface{},
+	body any,
+	timeout ...time.Duration,
+) (*http.Response, io.ReadCloser, error) {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	_, chain, attempts, src, err := r.prepare(body, timeout...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if src != nil {
+		defer src.close()
+	}
+
+	for i := 1; i <= attempts; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		attemptCtx := withAttempt(ctx, i)
+
+		req, err := r.newAttemptRequest(attemptCtx, headers, body, src)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		resp, err := chain.Do(req)
+		if err != nil {
+			if r.Retry && i < attempts && isRetryableError(err) {
+				sleepBackoff(ctx, nextBackoff(r, i, nil))
+				continue
+			}
+			return nil, nil, fmt.Errorf("do request: %w", err)
+		}
+
+		if r.Retry && i < attempts && isRetryableStatus(resp.StatusCode) {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			sleepBackoff(ctx, nextBackoff(r, i, resp))
+			continue
+		}
+
+		return resp, limitBody(resp.Body, r.MaxBodyBytes), nil
+	}
+
+	return nil, nil, fmt.Errorf("request failed after %d attempts", attempts)
+}
@@ -213,6 +213,80 @@ func TestRetryOnStatus503(t *testing.T) {
 	}
 }
 
+func TestRetryAfterSecondsHonored(t *testing.T) {
+	var hits int32
+	var firstAttemptAt, secondAttemptAt time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&hits, 1)
+		if n == 1 {
+			firstAttemptAt = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		secondAttemptAt = time.Now()
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	req := Request{
+		URL:        srv.URL,
+		Method:     http.MethodGet,
+		Type:       BodyJSON,
+		Retry:      true,
+		MaxBackoff: 2 * time.Second,
+	}
+
+	_, body, err := req.Perform(context.TODO(), nil, nil)
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if string(body) != "ok" {
+		t.Fatalf("unexpected body: %q", string(body))
+	}
+	if got := atomic.LoadInt32(&hits); got != 2 {
+		t.Fatalf("expected 2 attempts, got %d", got)
+	}
+	if wait := secondAttemptAt.Sub(firstAttemptAt); wait < 900*time.Millisecond {
+		t.Fatalf("expected retry to wait for Retry-After, only waited %v", wait)
+	}
+}
+
+func TestCustomBackoffOverridesPolicy(t *testing.T) {
+	var hits int32
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&hits, 1)
+		if n < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	req := Request{
+		URL:    srv.URL,
+		Method: http.MethodGet,
+		Type:   BodyJSON,
+		Retry:  true,
+		Backoff: func(attempt int, resp *http.Response) time.Duration {
+			atomic.AddInt32(&calls, 1)
+			return time.Millisecond
+		},
+	}
+
+	_, _, err := req.Perform(context.TODO(), nil, nil)
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("expected custom backoff to be called once, got %d", got)
+	}
+}
+
 func TestNoRetryOnStatus400(t *testing.T) {
 	var hits int32
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
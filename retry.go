@@ -3,8 +3,10 @@ package httpx
 import (
 	"context"
 	"errors"
+	"math/rand"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -12,6 +14,11 @@ import (
 const (
 	DefaultRetryAttempts = 3
 	DefaultBaseBackoff   = 200 * time.Millisecond
+
+	// DefaultMaxBackoff caps both the exponential backoff and any
+	// server-provided Retry-After value so a single bad header can't
+	// stall a caller indefinitely.
+	DefaultMaxBackoff = 30 * time.Second
 )
 
 // Retryable statuses: common transient ones.
@@ -46,13 +53,86 @@ func isRetryableError(err error) bool {
 	return false
 }
 
-func sleepBackoff(ctx context.Context, attempt int) {
-	// backoff: base * 2^(attempt-1)
+// nextBackoff computes how long to wait after the given attempt (1-based
+// index of the attempt that just failed) before the next one. resp is that
+// attempt's response, if any, and is used to honor a Retry-After header; it
+// is nil when the attempt failed with a network error.
+//
+// If r.Backoff is set, it takes full control of the policy. Otherwise a
+// Retry-After header on resp wins; failing that, the result is
+// base * 2^(attempt-1), capped at r.MaxBackoff (or DefaultMaxBackoff) and,
+// when r.Jitter is set, randomized via full jitter (a uniform draw between
+// 0 and the computed duration).
+func nextBackoff(r Request, attempt int, resp *http.Response) time.Duration {
+	if r.Backoff != nil {
+		return r.Backoff(attempt, resp)
+	}
+
+	max := r.MaxBackoff
+	if max <= 0 {
+		max = DefaultMaxBackoff
+	}
+
+	if resp != nil {
+		if d, ok := retryAfterDuration(resp, max); ok {
+			return d
+		}
+	}
+
 	d := DefaultBaseBackoff
 	for i := 1; i < attempt; i++ {
 		d *= 2
+		if d >= max {
+			d = max
+			break
+		}
+	}
+	if d > max {
+		d = max
+	}
+
+	if r.Jitter && d > 0 {
+		d = time.Duration(rand.Int63n(int64(d)) + 1)
 	}
 
+	return d
+}
+
+// retryAfterDuration parses the Retry-After header as either an integer
+// number of seconds or an HTTP-date, per RFC 9110 §10.2.3. The result is
+// capped at max. ok is false if the header is absent or unparsable.
+func retryAfterDuration(resp *http.Response, max time.Duration) (time.Duration, bool) {
+	v := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil {
+		d := time.Duration(secs) * time.Second
+		if d < 0 {
+			d = 0
+		}
+		if d > max {
+			d = max
+		}
+		return d, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		if d > max {
+			d = max
+		}
+		return d, true
+	}
+
+	return 0, false
+}
+
+func sleepBackoff(ctx context.Context, d time.Duration) {
 	timer := time.NewTimer(d)
 	defer timer.Stop()
 
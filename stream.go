@@ -0,0 +1,65 @@
+package httpx
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ErrBodyTooLarge is returned when a response body exceeds Request.MaxBodyBytes.
+var ErrBodyTooLarge = errors.New("httpx: response body exceeds MaxBodyBytes")
+
+// limitedBody wraps a response body in an io.LimitReader when max > 0,
+// turning an exceeded limit into ErrBodyTooLarge instead of silently
+// truncating, while still proxying Close to the underlying body.
+type limitedBody struct {
+	io.Reader
+	orig io.ReadCloser
+	max  int64
+	n    int64
+}
+
+func limitBody(body io.ReadCloser, max int64) *limitedBody {
+	lb := &limitedBody{orig: body, max: max}
+	if max > 0 {
+		lb.Reader = io.LimitReader(body, max+1)
+	} else {
+		lb.Reader = body
+	}
+	return lb
+}
+
+func (lb *limitedBody) Read(p []byte) (int, error) {
+	n, err := lb.Reader.Read(p)
+	lb.n += int64(n)
+	if lb.max > 0 && lb.n > lb.max {
+		return n, ErrBodyTooLarge
+	}
+	return n, err
+}
+
+func (lb *limitedBody) Close() error { return lb.orig.Close() }
+
+// decodeInto stream-decodes body into v, choosing JSON or XML based on
+// contentType (ignoring any "; charset=..." parameters), falling back to
+// fallback when contentType is empty or unrecognized.
+func decodeInto(body io.Reader, contentType string, fallback BodyType, v any) error {
+	ct := contentType
+	if idx := strings.IndexByte(ct, ';'); idx >= 0 {
+		ct = ct[:idx]
+	}
+	ct = strings.TrimSpace(strings.ToLower(ct))
+
+	switch {
+	case strings.Contains(ct, "xml"):
+		return xml.NewDecoder(body).Decode(v)
+	case strings.Contains(ct, "json"):
+		return json.NewDecoder(body).Decode(v)
+	case fallback == BodyXML:
+		return xml.NewDecoder(body).Decode(v)
+	default:
+		return json.NewDecoder(body).Decode(v)
+	}
+}
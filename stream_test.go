@@ -0,0 +1,133 @@
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPerformStreamReturnsUnreadBody(t *testing.T) {
+	const payload = "streamed bytes, read by the caller"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(payload))
+	}))
+	defer srv.Close()
+
+	req := Request{URL: srv.URL, Method: http.MethodGet, Type: BodyJSON}
+
+	resp, body, err := req.PerformStream(context.TODO(), nil, nil)
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	defer body.Close()
+
+	got, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("read stream: %v", err)
+	}
+	if string(got) != payload {
+		t.Fatalf("unexpected body: %q", string(got))
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestPerformStreamMaxBodyBytes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("way too much data for the cap"))
+	}))
+	defer srv.Close()
+
+	req := Request{URL: srv.URL, Method: http.MethodGet, Type: BodyJSON, MaxBodyBytes: 4}
+
+	_, body, err := req.PerformStream(context.TODO(), nil, nil)
+	if err != nil {
+		t.Fatalf("expected success opening the stream, got error: %v", err)
+	}
+	defer body.Close()
+
+	_, readErr := io.ReadAll(body)
+	if !errors.Is(readErr, ErrBodyTooLarge) {
+		t.Fatalf("expected ErrBodyTooLarge, got %v", readErr)
+	}
+}
+
+func TestPerformDecodeInto(t *testing.T) {
+	type payload struct {
+		Name string `json:"name"`
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(payload{Name: "hydra"})
+	}))
+	defer srv.Close()
+
+	var out payload
+	req := Request{URL: srv.URL, Method: http.MethodGet, Type: BodyJSON, DecodeInto: &out}
+
+	resp, body, err := req.Perform(context.TODO(), nil, nil)
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if body != nil {
+		t.Fatalf("expected nil body when DecodeInto is set, got %q", string(body))
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if out.Name != "hydra" {
+		t.Fatalf("expected decoded name %q, got %q", "hydra", out.Name)
+	}
+}
+
+func TestPerformMaxBodyBytesExceeded(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("way too much data for the cap"))
+	}))
+	defer srv.Close()
+
+	req := Request{URL: srv.URL, Method: http.MethodGet, Type: BodyJSON, MaxBodyBytes: 4}
+
+	_, _, err := req.Perform(context.TODO(), nil, nil)
+	if !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("expected ErrBodyTooLarge, got %v", err)
+	}
+}
+
+func TestPerformRetriesOversizedRetryableStatusBeforeFailing(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("way too much data for the cap"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	req := Request{URL: srv.URL, Method: http.MethodGet, Type: BodyJSON, Retry: true, MaxBodyBytes: 4}
+
+	_, body, err := req.Perform(context.TODO(), nil, nil)
+	if err != nil {
+		t.Fatalf("expected the oversized 503 to be retried rather than fail, got error: %v", err)
+	}
+	if string(body) != "ok" {
+		t.Fatalf("expected body %q, got %q", "ok", string(body))
+	}
+	if atomic.LoadInt32(&hits) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", hits)
+	}
+}
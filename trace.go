@@ -0,0 +1,89 @@
+package httpx
+
+import (
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"net/http/httptrace"
+	"time"
+)
+
+// Logger receives one structured record per request attempt when
+// Request.Trace is enabled. fields is an alternating key/value list
+// (slog-style: "key1", val1, "key2", val2, ...), so callers can forward
+// it to whatever structured logger they already use.
+type Logger interface {
+	Log(ctx context.Context, event string, fields ...any)
+}
+
+// NoopLogger discards every record, so Trace produces no log output.
+type NoopLogger struct{}
+
+// Log implements Logger by doing nothing.
+func (NoopLogger) Log(context.Context, string, ...any) {}
+
+// SlogLogger adapts a *slog.Logger to Logger. The zero value logs to
+// slog.Default().
+type SlogLogger struct {
+	Logger *slog.Logger
+}
+
+// Log implements Logger.
+func (l SlogLogger) Log(ctx context.Context, event string, fields ...any) {
+	logger := l.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Log(ctx, slog.LevelInfo, event, fields...)
+}
+
+// traceTimings accumulates the httptrace callback timestamps for a single
+// attempt. It is not safe for concurrent use, which is fine: the
+// callbacks for one attempt fire sequentially on the goroutine making
+// that request.
+type traceTimings struct {
+	dnsStart, dnsDone         time.Time
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+	wroteRequest              time.Time
+	firstByte                 time.Time
+}
+
+func newClientTrace(t *traceTimings) *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { t.dnsStart = time.Now() },
+		DNSDone:              func(httptrace.DNSDoneInfo) { t.dnsDone = time.Now() },
+		ConnectStart:         func(string, string) { t.connectStart = time.Now() },
+		ConnectDone:          func(string, string, error) { t.connectDone = time.Now() },
+		TLSHandshakeStart:    func() { t.tlsStart = time.Now() },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { t.tlsDone = time.Now() },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.wroteRequest = time.Now() },
+		GotFirstResponseByte: func() { t.firstByte = time.Now() },
+	}
+}
+
+func durationMS(start, end time.Time) float64 {
+	if start.IsZero() || end.IsZero() {
+		return 0
+	}
+	return float64(end.Sub(start)) / float64(time.Millisecond)
+}
+
+// fields builds the structured record for one attempt; the *_ms values
+// are durations in milliseconds.
+func (t *traceTimings) fields(started time.Time, method, url string, attempt, status int, bytesIn, bytesOut int64, headerNames []string) []any {
+	return []any{
+		"method", method,
+		"url", url,
+		"status", status,
+		"attempt", attempt,
+		"bytes_in", bytesIn,
+		"bytes_out", bytesOut,
+		"dns_ms", durationMS(t.dnsStart, t.dnsDone),
+		"connect_ms", durationMS(t.connectStart, t.connectDone),
+		"tls_ms", durationMS(t.tlsStart, t.tlsDone),
+		"ttfb_ms", durationMS(started, t.firstByte),
+		"total_ms", durationMS(started, time.Now()),
+		"headers", headerNames,
+	}
+}
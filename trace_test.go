@@ -0,0 +1,108 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+type recordingLogger struct {
+	mu     sync.Mutex
+	events []string
+	fields [][]any
+}
+
+func (l *recordingLogger) Log(ctx context.Context, event string, fields ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+	l.fields = append(l.fields, fields)
+}
+
+func fieldValue(fields []any, key string) (any, bool) {
+	for i := 0; i+1 < len(fields); i += 2 {
+		if fields[i] == key {
+			return fields[i+1], true
+		}
+	}
+	return nil, false
+}
+
+func TestTraceLogsOneRecordPerAttempt(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	logger := &recordingLogger{}
+	req := Request{URL: srv.URL, Method: http.MethodGet, Type: BodyJSON, Trace: true, Logger: logger}
+
+	_, _, err := req.Perform(context.TODO(), nil, nil)
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+
+	if len(logger.events) != 1 || logger.events[0] != "httpx.request" {
+		t.Fatalf("expected 1 httpx.request record, got %v", logger.events)
+	}
+	status, ok := fieldValue(logger.fields[0], "status")
+	if !ok || status != 200 {
+		t.Fatalf("expected status 200 in record, got %v (ok=%v)", status, ok)
+	}
+	bytesIn, ok := fieldValue(logger.fields[0], "bytes_in")
+	if !ok || bytesIn != int64(2) {
+		t.Fatalf("expected bytes_in=2, got %v (ok=%v)", bytesIn, ok)
+	}
+	if _, ok := fieldValue(logger.fields[0], "body"); ok {
+		t.Fatalf("expected body to be omitted when LogBodies is false")
+	}
+}
+
+func TestTraceLogsBodyWhenLogBodiesSet(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	logger := &recordingLogger{}
+	req := Request{URL: srv.URL, Method: http.MethodGet, Type: BodyJSON, Trace: true, Logger: logger, LogBodies: true}
+
+	_, _, err := req.Perform(context.TODO(), nil, nil)
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+
+	body, ok := fieldValue(logger.fields[0], "body")
+	if !ok || body != "hello" {
+		t.Fatalf("expected logged body %q, got %v (ok=%v)", "hello", body, ok)
+	}
+}
+
+func TestTraceLogsEachRetryAttempt(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	logger := &recordingLogger{}
+	req := Request{URL: srv.URL, Method: http.MethodGet, Type: BodyJSON, Retry: true, Trace: true, Logger: logger}
+
+	_, _, err := req.Perform(context.TODO(), nil, nil)
+	if err == nil {
+		t.Fatalf("expected error after exhausting retries")
+	}
+
+	if len(logger.events) != DefaultRetryAttempts {
+		t.Fatalf("expected %d records, got %d", DefaultRetryAttempts, len(logger.events))
+	}
+	for i, fields := range logger.fields {
+		attempt, ok := fieldValue(fields, "attempt")
+		if !ok || attempt != i+1 {
+			t.Fatalf("record %d: expected attempt %d, got %v", i, i+1, attempt)
+		}
+	}
+}